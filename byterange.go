@@ -0,0 +1,133 @@
+package ranger
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Header names used by ranged requests and responses.
+const (
+	headerNameRange         = "Range"
+	headerNameAcceptRanges  = "Accept-Ranges"
+	headerNameContentLength = "Content-Length"
+	headerNameContentRange  = "Content-Range"
+)
+
+// ErrRangeUnsupported is returned when the remote server does not
+// advertise support for byte range requests via Accept-Ranges.
+var ErrRangeUnsupported = errors.New("ranger: server does not support range requests")
+
+// ByteRange represents a contiguous span of bytes in a resource,
+// starting at Start and Length bytes long.
+type ByteRange struct {
+	Start  int64
+	Length int64
+}
+
+// End returns the last byte offset, inclusive, covered by br.
+func (br ByteRange) End() int64 {
+	return br.Start + br.Length - 1
+}
+
+// RangeHeader renders br as the value of an HTTP Range header.
+func (br ByteRange) RangeHeader() string {
+	return fmt.Sprintf("bytes=%d-%d", br.Start, br.End())
+}
+
+// ContentRange renders br as the value of an HTTP Content-Range header
+// for a resource of the given total size. It returns false if br does
+// not fall within [0, total).
+func (br ByteRange) ContentRange(total int64) (string, bool) {
+	if br.Length <= 0 || br.Start < 0 || br.End() >= total {
+		return "", false
+	}
+	return fmt.Sprintf("bytes %d-%d/%d", br.Start, br.End(), total), true
+}
+
+// cacheKey returns an opaque string identifying br within the resource
+// named by source, suitable for use as a ChunkCache key.
+func (br ByteRange) cacheKey(source string) string {
+	return fmt.Sprintf("%s\x00%d-%d", source, br.Start, br.Length)
+}
+
+// Chunks splits [start, contentLength) into consecutive ByteRanges, each
+// at most chunkSize bytes long.
+func Chunks(chunkSize, start, contentLength int64) []ByteRange {
+	if start >= contentLength {
+		return nil
+	}
+	chunks := make([]ByteRange, 0, (contentLength-start+chunkSize-1)/chunkSize)
+	for s := start; s < contentLength; s += chunkSize {
+		length := chunkSize
+		if s+length > contentLength {
+			length = contentLength - s
+		}
+		chunks = append(chunks, ByteRange{Start: s, Length: length})
+	}
+	return chunks
+}
+
+// ParseRange parses the value of an HTTP Range header into one ByteRange
+// per requested range, validating each against contentLength. An empty
+// header returns a nil slice and a nil error, meaning "the whole
+// resource".
+func ParseRange(header string, contentLength int64) ([]ByteRange, error) {
+	if header == "" {
+		return nil, nil
+	}
+
+	const prefix = "bytes="
+	if !strings.HasPrefix(header, prefix) {
+		return nil, fmt.Errorf("invalid range header %q: missing %q prefix", header, prefix)
+	}
+
+	var ranges []ByteRange
+	for _, spec := range strings.Split(header[len(prefix):], ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		start, end, ok := strings.Cut(spec, "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid range %q", spec)
+		}
+
+		var br ByteRange
+		if start == "" {
+			// Suffix range: the last N bytes of the resource.
+			n, err := strconv.ParseInt(end, 10, 64)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid suffix range %q", spec)
+			}
+			if n > contentLength {
+				n = contentLength
+			}
+			br = ByteRange{Start: contentLength - n, Length: n}
+		} else {
+			s, err := strconv.ParseInt(start, 10, 64)
+			if err != nil || s < 0 {
+				return nil, fmt.Errorf("invalid range start %q", spec)
+			}
+
+			e := contentLength - 1
+			if end != "" {
+				e, err = strconv.ParseInt(end, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid range end %q", spec)
+				}
+			}
+			if e > contentLength-1 {
+				e = contentLength - 1
+			}
+			if s > e {
+				return nil, fmt.Errorf("invalid range %q: start past end of resource", spec)
+			}
+			br = ByteRange{Start: s, Length: e - s + 1}
+		}
+		ranges = append(ranges, br)
+	}
+	return ranges, nil
+}
@@ -0,0 +1,145 @@
+package ranger
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ChunkCache stores and retrieves previously loaded chunk bytes, keyed
+// by an opaque string identifying a ByteRange within a resource.
+// Implementations must be safe for concurrent use.
+type ChunkCache interface {
+	// Get returns the cached bytes for key, if present.
+	Get(key string) ([]byte, bool)
+	// Put stores data under key, possibly evicting older entries.
+	Put(key string, data []byte)
+}
+
+// MemoryCache is a ChunkCache backed by an in-process LRU, bounded by a
+// total byte budget rather than an entry count.
+type MemoryCache struct {
+	mu     sync.Mutex
+	budget int64
+	size   int64
+	ll     *list.List
+	items  map[string]*list.Element
+}
+
+type memoryCacheEntry struct {
+	key  string
+	data []byte
+}
+
+// NewMemoryCache returns a MemoryCache that evicts least-recently-used
+// entries once the bytes it holds would otherwise exceed budget.
+func NewMemoryCache(budget int64) *MemoryCache {
+	return &MemoryCache{
+		budget: budget,
+		ll:     list.New(),
+		items:  make(map[string]*list.Element),
+	}
+}
+
+// Get implements ChunkCache.
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return el.Value.(*memoryCacheEntry).data, true
+}
+
+// Put implements ChunkCache.
+func (c *MemoryCache) Put(key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		entry := el.Value.(*memoryCacheEntry)
+		c.size += int64(len(data)) - int64(len(entry.data))
+		entry.data = data
+		for c.size > c.budget && c.ll.Len() > 0 {
+			c.evictOldest()
+		}
+		return
+	}
+
+	el := c.ll.PushFront(&memoryCacheEntry{key: key, data: data})
+	c.items[key] = el
+	c.size += int64(len(data))
+
+	for c.size > c.budget && c.ll.Len() > 0 {
+		c.evictOldest()
+	}
+}
+
+func (c *MemoryCache) evictOldest() {
+	el := c.ll.Back()
+	if el == nil {
+		return
+	}
+	c.ll.Remove(el)
+	entry := el.Value.(*memoryCacheEntry)
+	delete(c.items, entry.key)
+	c.size -= int64(len(entry.data))
+}
+
+// DiskCache is a ChunkCache that stores one file per key under a root
+// directory. Writes are atomic: data is written to a temporary file and
+// renamed into place, so a concurrent Get never observes a partially
+// written chunk.
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache returns a DiskCache rooted at dir, creating it if it
+// doesn't already exist.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating chunk cache directory %s: %w", dir, err)
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+// Get implements ChunkCache.
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// Put implements ChunkCache. A failure to persist data is not
+// observable by the caller; it just means a later Get will miss.
+func (c *DiskCache) Put(key string, data []byte) {
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+	_ = os.Rename(tmp.Name(), c.path(key))
+}
@@ -0,0 +1,75 @@
+package ranger
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewMemoryCache(10)
+
+	c.Put("a", []byte("aaaaa"))
+	c.Put("b", []byte("bbbbb"))
+
+	// Touch "a" so "b" becomes the least recently used entry.
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+
+	c.Put("c", []byte("ccccc"))
+
+	_, ok = c.Get("b")
+	assert.False(t, ok, "b should have been evicted")
+
+	data, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("aaaaa"), data)
+
+	data, ok = c.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("ccccc"), data)
+}
+
+func TestMemoryCacheMiss(t *testing.T) {
+	c := NewMemoryCache(1024)
+	_, ok := c.Get("missing")
+	assert.False(t, ok)
+}
+
+func TestDiskCacheRoundTrip(t *testing.T) {
+	c, err := NewDiskCache(filepath.Join(t.TempDir(), "chunks"))
+	assert.NoError(t, err)
+
+	_, ok := c.Get("missing")
+	assert.False(t, ok)
+
+	c.Put("key", []byte("hello chunk"))
+
+	data, ok := c.Get("key")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("hello chunk"), data)
+}
+
+func TestChunkLoadUsesCache(t *testing.T) {
+	var loads int
+	loader := LoaderFunc(func(ctx context.Context, br ByteRange) ([]byte, error) {
+		loads++
+		return []byte("data"), nil
+	})
+
+	cache := NewMemoryCache(1024)
+	br := ByteRange{Start: 0, Length: 4}
+	chunk := Chunk{Loader: loader, ByteRange: br, cache: cache, source: "http://example.com/file"}
+
+	data, err := chunk.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+	assert.Equal(t, 1, loads)
+
+	data, err = chunk.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+	assert.Equal(t, 1, loads, "second load should be served from cache")
+}
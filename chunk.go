@@ -0,0 +1,82 @@
+package ranger
+
+import "context"
+
+// Ranger splits a resource of a given length into fixed-size ByteRanges.
+type Ranger struct {
+	chunkSize int64
+}
+
+// NewRanger returns a Ranger that splits resources into chunkSize byte
+// chunks.
+func NewRanger(chunkSize int64) Ranger {
+	return Ranger{chunkSize: chunkSize}
+}
+
+// Ranges returns the ByteRanges covering a resource of the given length.
+func (r Ranger) Ranges(length int64) []ByteRange {
+	return Chunks(r.chunkSize, 0, length)
+}
+
+// Index returns the index, into the slice returned by Ranges, of the
+// chunk that contains the given offset.
+func (r Ranger) Index(offset int64) int {
+	return int(offset / r.chunkSize)
+}
+
+// Loader loads the bytes covered by a single ByteRange of a resource.
+// Load must be safe to call from multiple goroutines, must honor ctx's
+// cancellation, and on success must return exactly br.Length bytes.
+type Loader interface {
+	Load(ctx context.Context, br ByteRange) ([]byte, error)
+}
+
+// LoaderFunc adapts a plain function to the Loader interface.
+type LoaderFunc func(ctx context.Context, br ByteRange) ([]byte, error)
+
+// Load calls f.
+func (f LoaderFunc) Load(ctx context.Context, br ByteRange) ([]byte, error) {
+	return f(ctx, br)
+}
+
+// Chunk is a single, independently loadable unit of a RangedSource: the
+// ByteRange it covers, and the Loader used to fetch it. If cache is
+// set, Load consults it before falling back to Loader.
+type Chunk struct {
+	Loader
+	ByteRange
+
+	cache  ChunkCache
+	source string
+}
+
+// Load returns the bytes covered by c.ByteRange, serving them from
+// c.cache when possible and populating it on a miss. Load is called
+// from RangedSource's io.ReaderAt-shaped methods, which have no context
+// to propagate, so a miss is always fetched with context.Background();
+// it cannot be cancelled. Callers with a context to propagate, such as
+// HTTPReadSeeker, should use loadContext instead.
+func (c Chunk) Load() ([]byte, error) {
+	return c.loadContext(context.Background())
+}
+
+// loadContext is Load, fetching a cache miss with ctx instead of
+// context.Background().
+func (c Chunk) loadContext(ctx context.Context) ([]byte, error) {
+	key := c.ByteRange.cacheKey(c.source)
+	if c.cache != nil {
+		if data, ok := c.cache.Get(key); ok {
+			return data, nil
+		}
+	}
+
+	data, err := c.Loader.Load(ctx, c.ByteRange)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cache != nil {
+		c.cache.Put(key, data)
+	}
+	return data, nil
+}
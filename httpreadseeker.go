@@ -0,0 +1,87 @@
+package ranger
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// HTTPReadSeeker returns an io.ReadSeekCloser over rs's resource. Seek is
+// O(1): it only updates the current offset and issues no request. Read
+// loads whichever chunk covers the current offset through rs's Loader
+// and ChunkCache, exactly as ReadAt does, so a custom Loader (e.g. one
+// spreading fetches across mirrors) and WithCache are both honored. The
+// most recently loaded chunk is kept buffered, so sequential reads and
+// small seeks within it cost no additional fetch.
+func (rs RangedSource) HTTPReadSeeker(ctx context.Context) io.ReadSeekCloser {
+	return &httpReadSeeker{
+		ctx:    ctx,
+		chunks: rs.chunks,
+		ranger: rs.ranger,
+		length: rs.length,
+	}
+}
+
+// httpReadSeeker is the stateful io.ReadSeekCloser returned by
+// RangedSource.HTTPReadSeeker.
+type httpReadSeeker struct {
+	ctx    context.Context
+	chunks []Chunk
+	ranger Ranger
+	length int64
+
+	offset int64 // next byte Read will return
+
+	bufIndex int    // index into chunks of the buffered chunk; meaningful only while buf is non-nil
+	buf      []byte // data of chunks[bufIndex], or nil if no chunk is buffered yet
+}
+
+// Read implements io.Reader, loading (or reusing) whichever chunk covers
+// the current offset.
+func (h *httpReadSeeker) Read(p []byte) (int, error) {
+	if h.offset >= h.length {
+		return 0, io.EOF
+	}
+
+	index := h.ranger.Index(h.offset)
+	if h.buf == nil || index != h.bufIndex {
+		data, err := h.chunks[index].loadContext(h.ctx)
+		if err != nil {
+			return 0, err
+		}
+		h.buf = data
+		h.bufIndex = index
+	}
+
+	chunkStart := h.chunks[index].ByteRange.Start
+	n := copy(p, h.buf[h.offset-chunkStart:])
+	h.offset += int64(n)
+	return n, nil
+}
+
+// Seek implements io.Seeker. It never issues a request; the next Read
+// loads (or reuses) whichever chunk covers the new offset.
+func (h *httpReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	var target int64
+	switch whence {
+	case io.SeekStart:
+		target = offset
+	case io.SeekCurrent:
+		target = h.offset + offset
+	case io.SeekEnd:
+		target = h.length + offset
+	default:
+		return 0, errors.New("ranger: invalid whence")
+	}
+	if target < 0 {
+		return 0, errors.New("ranger: negative position")
+	}
+	h.offset = target
+	return target, nil
+}
+
+// Close implements io.Closer. It's a no-op: chunk loads aren't
+// long-lived connections, so there's nothing to tear down.
+func (h *httpReadSeeker) Close() error {
+	return nil
+}
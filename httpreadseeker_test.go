@@ -0,0 +1,122 @@
+package ranger
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rangeGetLoader is a Loader that issues a Range GET against server's
+// URL, used to exercise HTTPReadSeeker without the direct-HTTP fetch
+// path it used to hard-code.
+func rangeGetLoader(t *testing.T, url string) Loader {
+	return LoaderFunc(func(ctx context.Context, br ByteRange) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(headerNameRange, br.RangeHeader())
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusPartialContent {
+			t.Fatalf("unexpected status %s", resp.Status)
+		}
+		return io.ReadAll(resp.Body)
+	})
+}
+
+func TestHTTPReadSeekerSequentialRead(t *testing.T) {
+	content := makeData(1024 * 10)
+	server := makeHTTPServer(t, content)
+
+	rs := NewRangedSource(int64(len(content)), rangeGetLoader(t, server.URL), NewRanger(1024))
+	seeker := rs.HTTPReadSeeker(context.Background())
+	defer seeker.Close()
+
+	data, err := io.ReadAll(seeker)
+	assert.NoError(t, err)
+	assert.Equal(t, content, data)
+}
+
+func TestHTTPReadSeekerSeekForwardWithinWindow(t *testing.T) {
+	content := makeData(1024 * 10)
+	server := makeHTTPServer(t, content)
+
+	rs := NewRangedSource(int64(len(content)), rangeGetLoader(t, server.URL), NewRanger(1024))
+	seeker := rs.HTTPReadSeeker(context.Background())
+	defer seeker.Close()
+
+	buf := make([]byte, 100)
+	_, err := io.ReadFull(seeker, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, content[:100], buf)
+
+	pos, err := seeker.Seek(200, io.SeekStart)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(200), pos)
+
+	_, err = io.ReadFull(seeker, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, content[200:300], buf)
+}
+
+func TestHTTPReadSeekerSeekBackward(t *testing.T) {
+	content := makeData(1024 * 10)
+	server := makeHTTPServer(t, content)
+
+	rs := NewRangedSource(int64(len(content)), rangeGetLoader(t, server.URL), NewRanger(1024))
+	seeker := rs.HTTPReadSeeker(context.Background())
+	defer seeker.Close()
+
+	buf := make([]byte, 100)
+	_, err := io.ReadFull(seeker, buf)
+	assert.NoError(t, err)
+
+	pos, err := seeker.Seek(0, io.SeekStart)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), pos)
+
+	_, err = io.ReadFull(seeker, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, content[:100], buf)
+}
+
+func TestHTTPReadSeekerHonorsLoaderAndCache(t *testing.T) {
+	content := makeData(1024 * 10)
+	server := makeHTTPServer(t, content)
+
+	var loads int
+	loader := LoaderFunc(func(ctx context.Context, br ByteRange) ([]byte, error) {
+		loads++
+		return rangeGetLoader(t, server.URL).Load(ctx, br)
+	})
+
+	cache := NewMemoryCache(1024 * 1024)
+	rs := NewRangedSource(int64(len(content)), loader, NewRanger(1024), WithCache(cache), WithSource(server.URL))
+	seeker := rs.HTTPReadSeeker(context.Background())
+	defer seeker.Close()
+
+	buf := make([]byte, 100)
+	_, err := io.ReadFull(seeker, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, content[:100], buf)
+	assert.Equal(t, 1, loads)
+
+	// Re-reading a seen chunk through a fresh seeker should be served
+	// from cache, not re-fetched through the loader.
+	seeker2 := rs.HTTPReadSeeker(context.Background())
+	defer seeker2.Close()
+
+	_, err = io.ReadFull(seeker2, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, content[:100], buf)
+	assert.Equal(t, 1, loads, "second seeker should reuse the cached chunk")
+}
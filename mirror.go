@@ -0,0 +1,218 @@
+package ranger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultVirtualNodes is the number of virtual nodes placed on the hash
+// ring per mirror, smoothing out the distribution of chunks across
+// mirrors.
+const defaultVirtualNodes = 100
+
+// defaultCoolDown is how long a mirror is skipped after a chunk fetch
+// from it fails, before it's tried again.
+const defaultCoolDown = 30 * time.Second
+
+// MirrorSet is a set of equivalent base URLs that can each satisfy a
+// request for the same resource path.
+type MirrorSet struct {
+	mirrors []string
+}
+
+// NewMirrorSet returns a MirrorSet over the given base URLs.
+func NewMirrorSet(mirrors ...string) (MirrorSet, error) {
+	if len(mirrors) == 0 {
+		return MirrorSet{}, errors.New("mirror set must have at least one mirror")
+	}
+	return MirrorSet{mirrors: mirrors}, nil
+}
+
+// hashRing is a consistent-hashing ring of virtual nodes over a set of
+// mirrors.
+type hashRing struct {
+	nodes  []uint64
+	byNode map[uint64]string
+}
+
+func newHashRing(mirrors []string, virtualNodes int) *hashRing {
+	r := &hashRing{byNode: make(map[uint64]string, len(mirrors)*virtualNodes)}
+	for _, mirror := range mirrors {
+		for i := 0; i < virtualNodes; i++ {
+			h := hashString(fmt.Sprintf("%s#%d", mirror, i))
+			r.nodes = append(r.nodes, h)
+			r.byNode[h] = mirror
+		}
+	}
+	sort.Slice(r.nodes, func(i, j int) bool { return r.nodes[i] < r.nodes[j] })
+	return r
+}
+
+// pick returns the first mirror clockwise of key on the ring that isn't
+// in excluded.
+func (r *hashRing) pick(key uint64, excluded map[string]bool) (string, bool) {
+	n := len(r.nodes)
+	if n == 0 {
+		return "", false
+	}
+	start := sort.Search(n, func(i int) bool { return r.nodes[i] >= key })
+	for i := 0; i < n; i++ {
+		mirror := r.byNode[r.nodes[(start+i)%n]]
+		if !excluded[mirror] {
+			return mirror, true
+		}
+	}
+	return "", false
+}
+
+// hashString is the stable hash used to place mirrors and keys on the
+// ring.
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// ConsistentHashLoader loads chunks of a single resource path from a
+// MirrorSet, deterministically routing each ByteRange to a mirror by
+// consistent hashing on (path, chunk start). This maximizes the byte
+// range cache hit rate on any CDN fronting each mirror. A mirror that
+// fails a fetch is skipped for a cool-down window and the chunk is
+// retried against the next mirror on the ring.
+type ConsistentHashLoader struct {
+	path     string
+	client   *http.Client
+	ring     *hashRing
+	mirrors  []string
+	cooldown time.Duration
+
+	mu           sync.Mutex
+	coolingUntil map[string]time.Time
+}
+
+// NewConsistentHashLoader returns a Loader that fetches chunks of path
+// from mirrors, routed by consistent hashing.
+func NewConsistentHashLoader(client *http.Client, mirrors MirrorSet, path string) *ConsistentHashLoader {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &ConsistentHashLoader{
+		path:         path,
+		client:       client,
+		ring:         newHashRing(mirrors.mirrors, defaultVirtualNodes),
+		mirrors:      mirrors.mirrors,
+		cooldown:     defaultCoolDown,
+		coolingUntil: make(map[string]time.Time),
+	}
+}
+
+// Load implements Loader, fetching br from the mirror chosen by
+// consistent hashing, falling back to the next mirror on the ring if
+// the chosen one is cooling down or the fetch fails.
+func (l *ConsistentHashLoader) Load(ctx context.Context, br ByteRange) ([]byte, error) {
+	key := hashString(fmt.Sprintf("%s#%d", l.path, br.Start))
+
+	excluded := make(map[string]bool, len(l.mirrors))
+	var lastErr error
+	for attempt := 0; attempt < len(l.mirrors); attempt++ {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		mirror, ok := l.ring.pick(key, excluded)
+		if !ok {
+			break
+		}
+		excluded[mirror] = true
+
+		if l.isCoolingDown(mirror) {
+			continue
+		}
+
+		data, err := l.fetch(ctx, mirror, br)
+		if err == nil {
+			return data, nil
+		}
+		lastErr = err
+		l.markFailed(mirror)
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no mirror available")
+	}
+	return nil, fmt.Errorf("fetching %s from all mirrors: %w", br.RangeHeader(), lastErr)
+}
+
+func (l *ConsistentHashLoader) fetch(ctx context.Context, mirror string, br ByteRange) ([]byte, error) {
+	target, err := url.JoinPath(mirror, l.path)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(headerNameRange, br.RangeHeader())
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		if isRetryableStatus(resp.StatusCode) {
+			return nil, fmt.Errorf("%w: %s", errTransientServerError, resp.Status)
+		}
+		return nil, fmt.Errorf("fetching %s from %s: unexpected status %s", br.RangeHeader(), mirror, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != br.Length {
+		return nil, fmt.Errorf("fetching %s from %s: got %d bytes, want %d", br.RangeHeader(), mirror, len(data), br.Length)
+	}
+	return data, nil
+}
+
+func (l *ConsistentHashLoader) isCoolingDown(mirror string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	until, ok := l.coolingUntil[mirror]
+	return ok && time.Now().Before(until)
+}
+
+func (l *ConsistentHashLoader) markFailed(mirror string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.coolingUntil[mirror] = time.Now().Add(l.cooldown)
+}
+
+// NewClientWithMirrors returns a new http.Client that fetches chunks of
+// a resource from a set of equivalent mirrors, routed by consistent
+// hashing, in place of the single-URL fetch Client and RoundTripper use.
+func NewClientWithMirrors(chunkClient *http.Client, chunkSize, workers int64, mirrors []string) (*http.Client, error) {
+	set, err := NewMirrorSet(mirrors...)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{
+		Transport: roundTripper(func(r *http.Request) (*http.Response, error) {
+			req, err := NewRequestWithContext(r.Context(), r.Method, r.URL.String(), r.Body, chunkSize, workers)
+			if err != nil {
+				return nil, err
+			}
+			req.Loader = NewConsistentHashLoader(chunkClient, set, r.URL.Path)
+			return Do(chunkClient, req)
+		}),
+	}, nil
+}
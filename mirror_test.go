@@ -0,0 +1,65 @@
+package ranger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsistentHashLoaderIsStable(t *testing.T) {
+	content := makeData(1024)
+	server := makeHTTPServer(t, content)
+
+	mirrors, err := NewMirrorSet(server.URL, server.URL, server.URL)
+	assert.NoError(t, err)
+	loader := NewConsistentHashLoader(nil, mirrors, "/")
+
+	br := ByteRange{Start: 10, Length: 20}
+	first, err := loader.Load(context.Background(), br)
+	assert.NoError(t, err)
+	assert.Equal(t, content[10:30], first)
+
+	second, err := loader.Load(context.Background(), br)
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+}
+
+func TestConsistentHashLoaderFallsBackOnFailure(t *testing.T) {
+	content := makeData(1024)
+	good := makeHTTPServer(t, content)
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer bad.Close()
+
+	mirrors, err := NewMirrorSet(bad.URL, good.URL)
+	assert.NoError(t, err)
+	loader := NewConsistentHashLoader(nil, mirrors, "/")
+
+	data, err := loader.Load(context.Background(), ByteRange{Start: 0, Length: 16})
+	assert.NoError(t, err)
+	assert.Equal(t, content[0:16], data)
+}
+
+func TestNewMirrorSetRequiresAtLeastOneMirror(t *testing.T) {
+	_, err := NewMirrorSet()
+	assert.Error(t, err)
+}
+
+func TestConsistentHashLoaderHonorsContextCancellation(t *testing.T) {
+	content := makeData(1024)
+	server := makeHTTPServer(t, content)
+
+	mirrors, err := NewMirrorSet(server.URL)
+	assert.NoError(t, err)
+	loader := NewConsistentHashLoader(nil, mirrors, "/")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = loader.Load(ctx, ByteRange{Start: 0, Length: 16})
+	assert.ErrorIs(t, err, context.Canceled)
+}
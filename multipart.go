@@ -0,0 +1,97 @@
+package ranger
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// multipartBoundaryBytes is the amount of random data hex-encoded into
+// each synthesized multipart/byteranges boundary.
+const multipartBoundaryBytes = 30
+
+// newBoundary returns a new random MIME boundary token.
+func newBoundary() string {
+	buf := make([]byte, multipartBoundaryBytes)
+	_, _ = rand.Read(buf)
+	return fmt.Sprintf("%x", buf)
+}
+
+// multipartRange is a single part of a multipart/byteranges response: the
+// literal bytes of its MIME part header, and the sub-chunks that make up
+// its body.
+type multipartRange struct {
+	header []byte
+	chunks []ByteRange
+}
+
+// partHeader renders the MIME part header for a single range of a
+// multipart/byteranges response, per RFC 7233 §4.1.
+func partHeader(boundary, contentType, contentRange string) []byte {
+	var b []byte
+	b = append(b, "--"+boundary+"\r\n"...)
+	if contentType != "" {
+		b = append(b, "Content-Type: "+contentType+"\r\n"...)
+	}
+	b = append(b, "Content-Range: "+contentRange+"\r\n\r\n"...)
+	return b
+}
+
+// buildMultipartRanges lays out one multipartRange per requested range,
+// each further split into chunkSize chunks, and returns them alongside
+// the exact byte length of the encoded multipart body.
+func buildMultipartRanges(ranges []ByteRange, chunkSize, contentLength int64, boundary, contentType string) ([]multipartRange, int64, error) {
+	parts := make([]multipartRange, len(ranges))
+	var total int64
+	for i, br := range ranges {
+		cr, ok := br.ContentRange(contentLength)
+		if !ok {
+			return nil, 0, fmt.Errorf("unable to generate Content-Range header for range %s", br.RangeHeader())
+		}
+		header := partHeader(boundary, contentType, cr)
+		parts[i] = multipartRange{
+			header: header,
+			chunks: Chunks(chunkSize, br.Start, br.Start+br.Length),
+		}
+		total += int64(len(header)) + br.Length + 2 // 2 == trailing "\r\n"
+	}
+	total += int64(len("--" + boundary + "--\r\n"))
+	return parts, total, nil
+}
+
+// multipartBody streams parts as a multipart/byteranges payload. Each
+// part's chunks are fetched and parallelized across workers, but parts
+// themselves are written out in request order.
+func multipartBody(c *http.Client, r *Request, boundary string, parts []multipartRange) io.ReadCloser {
+	read, write := io.Pipe()
+	go func() {
+		var err error
+		defer func() {
+			if err != nil {
+				_ = write.CloseWithError(err)
+				return
+			}
+			_ = write.Close()
+		}()
+
+		for _, part := range parts {
+			if _, err = write.Write(part.header); err != nil {
+				return
+			}
+
+			partBody := newRemoteFileReader(c, r, part.chunks)
+			_, err = io.Copy(write, partBody)
+			_ = partBody.Close()
+			if err != nil {
+				return
+			}
+			if _, err = write.Write([]byte("\r\n")); err != nil {
+				return
+			}
+		}
+
+		_, err = write.Write([]byte("--" + boundary + "--\r\n"))
+	}()
+	return read
+}
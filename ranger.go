@@ -7,8 +7,7 @@ import (
 	"io"
 	"net/http"
 	"strconv"
-
-	"github.com/sourcegraph/conc/stream"
+	"time"
 )
 
 // Request is a ranged http.Request.
@@ -16,6 +15,24 @@ type Request struct {
 	*http.Request
 	chunkSize int64
 	workers   int64
+
+	// MaxRetries is the number of times a chunk is retried after a
+	// transient error before Do gives up and aborts the response body.
+	MaxRetries int
+	// Backoff returns how long to wait before retrying a chunk for the
+	// given (1-indexed) attempt. If nil, an exponential backoff with
+	// jitter is used.
+	Backoff func(attempt int) time.Duration
+
+	// Cache, if set, is consulted for each chunk before it is fetched
+	// over HTTP, and populated with freshly fetched chunks.
+	Cache ChunkCache
+
+	// Loader, if set, is used to fetch each chunk instead of issuing a
+	// Range GET against the Request's own URL. This lets callers plug in
+	// alternative fetch strategies, such as routing chunks across a set
+	// of mirrors.
+	Loader Loader
 }
 
 // NewRequestWithContext returns a new Request.
@@ -39,9 +56,11 @@ func NewRequestWithContext(
 		return nil, errors.New("buffer number must be non-zero")
 	}
 	return &Request{
-		Request:   req,
-		chunkSize: chunkSize,
-		workers:   workers,
+		Request:    req,
+		chunkSize:  chunkSize,
+		workers:    workers,
+		MaxRetries: defaultMaxRetries,
+		Backoff:    defaultBackoff,
 	}, nil
 }
 
@@ -56,6 +75,9 @@ func NewRequest(method, url string, body io.Reader, chunkSize, workers int64) (*
 // The returned Response.Body is a ReadCloser that reads from the remote file in chunks.
 // Chunks are fetched parallelly and are written to the ReadCloser in order.
 // If r.Method is HEAD, the response is fetched without ranging, in one request.
+// If the requested Range header names more than one range, the response body
+// is encoded as multipart/byteranges per RFC 7233 §4.1, with each range
+// fetched and chunked independently.
 func Do(c *http.Client, r *Request) (*http.Response, error) {
 	if r == nil || r.Request == nil {
 		return nil, errors.New("request cannot be nil")
@@ -103,9 +125,11 @@ func Do(c *http.Client, r *Request) (*http.Response, error) {
 
 	headers := probeResp.Header.Clone()
 
+	var body io.ReadCloser
 	switch len(chunks) {
 	case 0:
 		chunks = Chunks(r.chunkSize, 0, contentLength)
+		body = newRemoteFileReader(c, r, chunks)
 	case 1:
 		cr, ok := chunks[0].ContentRange(contentLength)
 		if !ok {
@@ -113,19 +137,18 @@ func Do(c *http.Client, r *Request) (*http.Response, error) {
 		}
 		headers.Set(headerNameContentRange, cr)
 		contentLength = chunks[0].Length
+		body = newRemoteFileReader(c, r, Chunks(r.chunkSize, chunks[0].Start, chunks[0].Start+chunks[0].Length))
 	default:
-		return nil, errors.New("ranger does not support fetching multiple ranges")
-	}
-
-	read, write := io.Pipe()
-	remoteFile := &remoteFileReader{
-		PipeReader: read,
-		client:     c,
-		url:        r.URL,
-		chunks:     chunks,
+		boundary := newBoundary()
+		parts, total, err := buildMultipartRanges(chunks, r.chunkSize, contentLength, boundary, headers.Get("Content-Type"))
+		if err != nil {
+			return nil, err
+		}
+		headers.Set("Content-Type", fmt.Sprintf("multipart/byteranges; boundary=%s", boundary))
+		headers.Del(headerNameContentRange)
+		contentLength = total
+		body = multipartBody(c, r, boundary, parts)
 	}
-	fetchers := stream.New().WithMaxGoroutines(int(r.workers))
-	go remoteFile.fetchChunks(ctx, fetchers, write)
 
 	rangeResponse := http.Response{
 		Status:     probeResp.Status,
@@ -138,17 +161,29 @@ func Do(c *http.Client, r *Request) (*http.Response, error) {
 		// Synthesised fields.
 		ContentLength: contentLength,
 		Header:        headers,
-		Body:          remoteFile,
+		Body:          body,
 		Request:       r.Request,
 	}
 	return &rangeResponse, nil
 }
 
+// ClientOption configures optional behavior of a ranging http.Client or
+// http.RoundTripper.
+type ClientOption func(*Request)
+
+// WithChunkCache makes every chunk fetch consult cache before issuing a
+// Range GET, and populates it with freshly fetched chunks.
+func WithChunkCache(cache ChunkCache) ClientOption {
+	return func(r *Request) {
+		r.Cache = cache
+	}
+}
+
 // NewClient returns a new http.Client that uses a ranging http.RoundTripper.
 // Chunks are chunkSize bytes long. A maximum of workers chunks are fetched concurrently.
-func NewClient(chunkClient *http.Client, chunkSize, workers int64) *http.Client {
+func NewClient(chunkClient *http.Client, chunkSize, workers int64, opts ...ClientOption) *http.Client {
 	return &http.Client{
-		Transport: NewRoundTripper(chunkClient, chunkSize, workers),
+		Transport: NewRoundTripper(chunkClient, chunkSize, workers, opts...),
 	}
 }
 
@@ -161,7 +196,7 @@ func (r roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 // NewRoundTripper returns a new http.RoundTripper that fetches requests in chunks.
 // Chunks are chunkSize bytes long. A maximum of workers chunks are fetched concurrently.
 // If chunkClient is nil, http.DefaultClient is used.
-func NewRoundTripper(chunkClient *http.Client, chunkSize, workers int64) http.RoundTripper {
+func NewRoundTripper(chunkClient *http.Client, chunkSize, workers int64, opts ...ClientOption) http.RoundTripper {
 	return roundTripper(func(r *http.Request) (*http.Response, error) {
 		req, err := NewRequestWithContext(
 			r.Context(),
@@ -174,6 +209,9 @@ func NewRoundTripper(chunkClient *http.Client, chunkSize, workers int64) http.Ro
 		if err != nil {
 			return nil, err
 		}
+		for _, opt := range opts {
+			opt(req)
+		}
 		return Do(chunkClient, req)
 	})
 }
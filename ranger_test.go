@@ -2,7 +2,10 @@ package ranger
 
 import (
 	"bytes"
+	"io"
 	"math/rand"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -52,11 +55,6 @@ func TestDo(t *testing.T) {
 				contentRangeHeader: "bytes 42-83/10240",
 			},
 		},
-		{
-			name:        "error fetching multiple ranges",
-			rangeHeader: "bytes=100-200,300-400",
-			err:         true,
-		},
 		{
 			name:      "1 byte chunk",
 			chunkSize: 1,
@@ -94,12 +92,15 @@ func TestDo(t *testing.T) {
 
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
-			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			req, err := NewRequest(http.MethodGet, server.URL, nil, testCase.chunkSize, testCase.workers)
+			if testCase.err && err != nil {
+				return
+			}
 			assert.NoError(t, err)
 			if testCase.rangeHeader != "" {
 				req.Header.Set("Range", testCase.rangeHeader)
 			}
-			resp, err := Do(nil, req, testCase.chunkSize, testCase.workers)
+			resp, err := Do(nil, req)
 			if testCase.err {
 				assert.Error(t, err)
 			} else {
@@ -113,6 +114,42 @@ func TestDo(t *testing.T) {
 	}
 }
 
+func TestDoMultipartByteranges(t *testing.T) {
+	content := makeData(1024 * 10)
+	server := makeHTTPServer(t, content)
+
+	req, err := NewRequest(http.MethodGet, server.URL, nil, 64, 10)
+	assert.NoError(t, err)
+	req.Header.Set("Range", "bytes=100-200,300-400")
+
+	resp, err := Do(nil, req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/byteranges", mediaType)
+
+	mr := multipart.NewReader(resp.Body, params["boundary"])
+
+	part, err := mr.NextPart()
+	assert.NoError(t, err)
+	assert.Equal(t, "bytes 100-200/10240", part.Header.Get("Content-Range"))
+	data, err := io.ReadAll(part)
+	assert.NoError(t, err)
+	assert.Equal(t, content[100:201], data)
+
+	part, err = mr.NextPart()
+	assert.NoError(t, err)
+	assert.Equal(t, "bytes 300-400/10240", part.Header.Get("Content-Range"))
+	data, err = io.ReadAll(part)
+	assert.NoError(t, err)
+	assert.Equal(t, content[300:401], data)
+
+	_, err = mr.NextPart()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
 func TestNewClient(t *testing.T) {
 	content := makeData(1024 * 10)
 	server := makeHTTPServer(t, content)
@@ -0,0 +1,118 @@
+package ranger
+
+import (
+	"io"
+	"sync"
+)
+
+// ChannellingReader is an io.Reader that reads a sequence of io.Readers
+// submitted via Send, in submission order, as though they were
+// concatenated with io.MultiReader. Unlike io.MultiReader, the full
+// sequence doesn't need to be known upfront: producers can Send readers
+// as they become available and call Finish once no more will arrive.
+type ChannellingReader struct {
+	readers chan io.Reader
+	current io.Reader
+}
+
+// NewChannellingReader returns a ChannellingReader whose internal queue
+// can hold up to bufferSize readers before Send blocks.
+func NewChannellingReader(bufferSize int) *ChannellingReader {
+	return &ChannellingReader{readers: make(chan io.Reader, bufferSize)}
+}
+
+// Send submits r to be read after every reader submitted before it.
+// Send blocks once the queue is full.
+func (c *ChannellingReader) Send(r io.Reader) {
+	c.readers <- r
+}
+
+// Finish signals that no more readers will be sent. It must be called
+// exactly once, after the last call to Send.
+func (c *ChannellingReader) Finish() {
+	close(c.readers)
+}
+
+// Read implements io.Reader, reading from the current sub-reader until
+// it's exhausted, then blocking on the next one submitted via Send.
+func (c *ChannellingReader) Read(p []byte) (int, error) {
+	for {
+		if c.current == nil {
+			r, ok := <-c.readers
+			if !ok {
+				return 0, io.EOF
+			}
+			c.current = r
+		}
+
+		n, err := c.current.Read(p)
+		if err == io.EOF {
+			c.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+// Close implements io.Closer. It is always safe to call, including
+// before Finish; any readers still queued are simply discarded.
+func (c *ChannellingReader) Close() error {
+	return nil
+}
+
+// bufferedReader is an io.Reader for a single chunk's bytes that can be
+// handed off (e.g. to a ChannellingReader) before the chunk has actually
+// been fetched. Read blocks only until fill is called. drained is
+// closed once the reader has yielded its last byte (or its error),
+// letting a producer wait for a chunk to be fully consumed before
+// reusing the capacity it occupied - the mechanism fetchChunks uses to
+// bound how many fetched chunks can sit buffered ahead of the reader.
+type bufferedReader struct {
+	ready     chan struct{}
+	drained   chan struct{}
+	drainOnce sync.Once
+	data      []byte
+	err       error
+	off       int
+}
+
+// newBufferedReader returns a bufferedReader whose Read blocks until
+// fill is called.
+func newBufferedReader() *bufferedReader {
+	return &bufferedReader{ready: make(chan struct{}), drained: make(chan struct{})}
+}
+
+// fill makes data (or err) available to Read, unblocking it. It must be
+// called exactly once. A non-nil err marks the reader drained
+// immediately, since there will be no bytes for a consumer to read.
+func (b *bufferedReader) fill(data []byte, err error) {
+	b.data = data
+	b.err = err
+	close(b.ready)
+	if err != nil {
+		b.markDrained()
+	}
+}
+
+// markDrained closes drained, if it hasn't been already.
+func (b *bufferedReader) markDrained() {
+	b.drainOnce.Do(func() { close(b.drained) })
+}
+
+// Read implements io.Reader. It blocks until fill has been called.
+func (b *bufferedReader) Read(p []byte) (int, error) {
+	<-b.ready
+	if b.off >= len(b.data) {
+		if b.err != nil {
+			return 0, b.err
+		}
+		b.markDrained()
+		return 0, io.EOF
+	}
+	n := copy(p, b.data[b.off:])
+	b.off += n
+	return n, nil
+}
@@ -10,6 +10,7 @@ type RangedSource struct {
 	chunks []Chunk
 	ranger Ranger
 	length int64
+	source string
 }
 
 func (rs RangedSource) ReadAt(p []byte, off int64) (n int, err error) {
@@ -93,18 +94,51 @@ func (rs RangedSource) PreloadingReader(n int) io.ReadCloser {
 	return r
 }
 
-func NewRangedSource(length int64, loader Loader, ranger Ranger) RangedSource {
+// RangedSourceOption configures optional behavior of a RangedSource.
+type RangedSourceOption func(*rangedSourceOptions)
+
+type rangedSourceOptions struct {
+	cache  ChunkCache
+	source string
+}
+
+// WithCache makes the RangedSource consult cache before invoking its
+// Loader for each chunk, and populates it with freshly loaded chunks.
+func WithCache(cache ChunkCache) RangedSourceOption {
+	return func(o *rangedSourceOptions) {
+		o.cache = cache
+	}
+}
+
+// WithSource sets the identifier - typically the resource URL - combined
+// with each ByteRange to build cache keys, so a single ChunkCache can be
+// shared safely across multiple resources.
+func WithSource(source string) RangedSourceOption {
+	return func(o *rangedSourceOptions) {
+		o.source = source
+	}
+}
+
+func NewRangedSource(length int64, loader Loader, ranger Ranger, opts ...RangedSourceOption) RangedSource {
+	var o rangedSourceOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	chunks := make([]Chunk, 0)
 	for _, br := range ranger.Ranges(length) {
 		chunks = append(chunks, Chunk{
 			Loader:    loader,
 			ByteRange: br,
+			cache:     o.cache,
+			source:    o.source,
 		})
 	}
 	rf := RangedSource{
 		chunks: chunks,
 		ranger: ranger,
 		length: length,
+		source: o.source,
 	}
 
 	return rf
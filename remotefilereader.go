@@ -0,0 +1,275 @@
+package ranger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// defaultMaxRetries is the number of times fetchChunk retries a chunk
+// after a transient failure before giving up on the whole request.
+const defaultMaxRetries = 3
+
+// defaultBackoff returns an exponential backoff duration with jitter for
+// the given (1-indexed) retry attempt.
+func defaultBackoff(attempt int) time.Duration {
+	base := time.Duration(1<<uint(attempt)) * 100 * time.Millisecond
+	return base + time.Duration(rand.Int63n(int64(base)))
+}
+
+// errTransientServerError marks a non-206 response whose status
+// isRetryableStatus accepts (408, 429 or 5xx) as a retryable failure.
+var errTransientServerError = errors.New("transient server error")
+
+// remoteFileReader streams the bytes of a ranged HTTP request through a
+// ChannellingReader. fetchChunks queues a bufferedReader per chunk
+// immediately, in chunk order, then fetches chunks in parallel; each
+// bufferedReader unblocks as soon as its own chunk is ready, independent
+// of how far the other workers have gotten.
+type remoteFileReader struct {
+	*ChannellingReader
+	client     *http.Client
+	url        *url.URL
+	chunks     []ByteRange
+	maxRetries int
+	backoff    func(attempt int) time.Duration
+	cache      ChunkCache
+	loader     Loader
+	cancel     context.CancelFunc
+}
+
+// newRemoteFileReader returns an io.ReadCloser that streams chunks in
+// order, fetching them in parallel across r.workers goroutines. Closing
+// it before all chunks are read cancels every in-flight and pending
+// fetch.
+func newRemoteFileReader(c *http.Client, r *Request, chunks []ByteRange) io.ReadCloser {
+	ctx, cancel := context.WithCancel(r.Context())
+	rf := &remoteFileReader{
+		ChannellingReader: NewChannellingReader(len(chunks)),
+		client:            c,
+		url:               r.URL,
+		chunks:            chunks,
+		maxRetries:        r.MaxRetries,
+		backoff:           r.Backoff,
+		cache:             r.Cache,
+		loader:            r.Loader,
+		cancel:            cancel,
+	}
+	go rf.fetchChunks(ctx, int(r.workers))
+	return rf
+}
+
+// Close cancels every in-flight and pending chunk fetch. It shadows
+// ChannellingReader.Close, which alone cannot stop the background
+// fetchChunks goroutine.
+func (r *remoteFileReader) Close() error {
+	r.cancel()
+	return r.ChannellingReader.Close()
+}
+
+// fetchChunks queues a bufferedReader for each of r.chunks on the
+// ChannellingReader up front, then fetches the chunks across up to
+// workers goroutines, filling each bufferedReader as its chunk
+// completes. Ordering is handled entirely by the queue: a slow or
+// saturated fetch for one chunk never blocks another chunk's fetch, or
+// delays the caller from reading chunks that are already ready.
+//
+// A worker's slot isn't freed when its fetch completes, but only once
+// the chunk it fetched has been fully read (or the request is
+// cancelled). This bounds the number of fetched-but-unread chunks
+// sitting in memory to workers, restoring the backpressure a single
+// io.Pipe used to provide: a fast link feeding a slow reader can't race
+// ahead and buffer the whole resource.
+func (r *remoteFileReader) fetchChunks(ctx context.Context, workers int) {
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for _, chunk := range r.chunks {
+		chunk := chunk
+		buf := newBufferedReader()
+		r.Send(buf)
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			buf.fill(nil, ctx.Err())
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				select {
+				case <-buf.drained:
+				case <-ctx.Done():
+				}
+				<-sem
+			}()
+			data, err := r.fetchChunk(ctx, chunk)
+			buf.fill(data, err)
+		}()
+	}
+	wg.Wait()
+	r.Finish()
+}
+
+// fetchChunk fetches br in full, resuming from the unreceived portion on
+// transient errors until it succeeds or r.maxRetries is exhausted. If a
+// cache is configured, it is consulted first and populated on a miss.
+func (r *remoteFileReader) fetchChunk(ctx context.Context, br ByteRange) ([]byte, error) {
+	key := br.cacheKey(r.url.String())
+	if r.cache != nil {
+		if data, ok := r.cache.Get(key); ok {
+			return data, nil
+		}
+	}
+
+	backoff := r.backoff
+	if backoff == nil {
+		backoff = defaultBackoff
+	}
+
+	if r.loader != nil {
+		data, err := r.fetchChunkViaLoader(ctx, br, backoff)
+		if err == nil && r.cache != nil {
+			r.cache.Put(key, data)
+		}
+		return data, err
+	}
+
+	buf := make([]byte, 0, br.Length)
+	remaining := br
+	var lastErr error
+	for attempt := 0; attempt <= r.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff(attempt)):
+			}
+		}
+
+		n, err := r.fetchInto(ctx, remaining, &buf)
+		if err == nil {
+			if int64(len(buf)) == br.Length {
+				if r.cache != nil {
+					r.cache.Put(key, buf)
+				}
+				return buf, nil
+			}
+			err = fmt.Errorf("fetching chunk %s: got %d bytes, want %d", br.RangeHeader(), len(buf), br.Length)
+		}
+		if ctx.Err() != nil || !isRetryableError(err) {
+			return nil, err
+		}
+		lastErr = err
+		remaining = ByteRange{Start: remaining.Start + n, Length: remaining.Length - n}
+		if remaining.Length <= 0 {
+			if r.cache != nil {
+				r.cache.Put(key, buf)
+			}
+			return buf, nil
+		}
+	}
+	return nil, fmt.Errorf("fetching chunk %s after %d attempts: %w", br.RangeHeader(), r.maxRetries+1, lastErr)
+}
+
+// fetchChunkViaLoader fetches br through r.loader instead of issuing an
+// HTTP request directly, retrying the whole chunk on failure. Unlike the
+// direct HTTP path, a Loader returns chunk data atomically, so there's
+// no partial buffer to resume from between attempts.
+func (r *remoteFileReader) fetchChunkViaLoader(ctx context.Context, br ByteRange, backoff func(attempt int) time.Duration) ([]byte, error) {
+	var lastErr error
+	for attempt := 0; attempt <= r.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff(attempt)):
+			}
+		}
+
+		data, err := r.loader.Load(ctx, br)
+		if err == nil {
+			return data, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("fetching chunk %s after %d attempts: %w", br.RangeHeader(), r.maxRetries+1, lastErr)
+}
+
+// fetchInto issues a Range GET for br and appends the response body to
+// *buf, returning the number of bytes appended even when err != nil so
+// the caller can resume the download from the right offset.
+func (r *remoteFileReader) fetchInto(ctx context.Context, br ByteRange, buf *[]byte) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set(headerNameRange, br.RangeHeader())
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		if isRetryableStatus(resp.StatusCode) {
+			return 0, fmt.Errorf("%w: %s", errTransientServerError, resp.Status)
+		}
+		return 0, fmt.Errorf("fetching %s: unexpected status %s", br.RangeHeader(), resp.Status)
+	}
+
+	n, err := io.Copy(appendWriter{buf}, resp.Body)
+	return n, err
+}
+
+// isRetryableStatus reports whether a non-206 status code is worth
+// retrying: a request timeout, a rate limit, or any 5xx server error.
+// Any other non-206 status (redirects, 4xx client errors, 416, etc.) is
+// terminal, since retrying it would just stream an error page or the
+// whole resource into the chunk buffer as if it were valid data.
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	default:
+		return code >= http.StatusInternalServerError
+	}
+}
+
+// appendWriter appends every Write to *buf, letting io.Copy grow a byte
+// slice without an intermediate bytes.Buffer.
+type appendWriter struct {
+	buf *[]byte
+}
+
+func (w appendWriter) Write(p []byte) (int, error) {
+	*w.buf = append(*w.buf, p...)
+	return len(p), nil
+}
+
+// isRetryableError reports whether err is a transient failure that's
+// worth retrying: an unexpected EOF, a network-level error, or a
+// response status that isRetryableStatus accepts. Context cancellation
+// is never retryable.
+func isRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, errTransientServerError) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}